@@ -0,0 +1,109 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+)
+
+// Age describes how far an installed framework version has drifted from
+// the latest one available in the index, both in release count and in
+// wall-clock time (the libyear technique).
+type Age struct {
+	Name           string
+	CurrentVersion string
+	LatestVersion  string
+	LatestCreated  time.Time
+	VersionsBehind int
+	Duration       time.Duration
+}
+
+// Age computes how far behind currentVersion is from the latest
+// non-prerelease release of name. VersionsBehind counts releases strictly
+// newer than currentVersion and no newer than the resolved LatestVersion,
+// so it never reports more releases behind than the one it names as
+// latest; Duration is the time elapsed between currentVersion's Created
+// timestamp and the latest release's.
+func (i IndexFile) Age(name, currentVersion string) (Age, error) {
+	vs, ok := i.Entries[name]
+	if !ok || len(vs) == 0 {
+		return Age{}, errors.Errorf("no framework of given name %s found", name)
+	}
+
+	latest, err := i.GetLatest(name)
+	if err != nil {
+		return Age{}, err
+	}
+
+	latestVersion, err := semver.NewVersion(latest.Version)
+	if err != nil {
+		return Age{}, errors.Wrapf(err, "parsing latest version %s", latest.Version)
+	}
+
+	cur, err := semver.NewVersion(currentVersion)
+	if err != nil {
+		return Age{}, errors.Wrapf(err, "parsing current version %s", currentVersion)
+	}
+
+	var currentCreated time.Time
+	foundCurrent := false
+	behind := 0
+	for _, bv := range vs {
+		v, err := semver.NewVersion(bv.Version)
+		if err != nil {
+			continue
+		}
+		if v.Equal(cur) {
+			currentCreated = bv.Created
+			foundCurrent = true
+			continue
+		}
+		if v.GreaterThan(cur) && !v.GreaterThan(latestVersion) {
+			behind++
+		}
+	}
+	if !foundCurrent {
+		return Age{}, errors.Errorf("version %s of %s not found in index", currentVersion, name)
+	}
+
+	var duration time.Duration
+	if !currentCreated.IsZero() && !latest.Created.IsZero() {
+		duration = latest.Created.Sub(currentCreated)
+	}
+
+	return Age{
+		Name:           name,
+		CurrentVersion: currentVersion,
+		LatestVersion:  latest.Version,
+		LatestCreated:  latest.Created,
+		VersionsBehind: behind,
+		Duration:       duration,
+	}, nil
+}
+
+// Report computes Age for every (name, version) pair in installed,
+// skipping entries whose name or version isn't present in the index
+// rather than failing the whole report. It's the basis for a
+// `kudo report drift` style summary across a cluster.
+func (i IndexFile) Report(installed map[string]string) []Age {
+	ages := make([]Age, 0, len(installed))
+	for name, version := range installed {
+		age, err := i.Age(name, version)
+		if err != nil {
+			continue
+		}
+		ages = append(ages, age)
+	}
+	return ages
+}
+
+// TotalDrift sums Duration across ages, giving the total "framework-years"
+// (expressed as a time.Duration) of staleness across a report.
+func TotalDrift(ages []Age) time.Duration {
+	var total time.Duration
+	for _, a := range ages {
+		total += a.Duration
+	}
+	return total
+}
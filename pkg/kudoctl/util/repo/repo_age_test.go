@@ -0,0 +1,59 @@
+package repo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIndexFileAge(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	t2 := time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	idx := IndexFile{Entries: map[string]BundleVersions{
+		"foo": {
+			{Metadata: &Metadata{Version: "1.0.0"}, Created: t0},
+			{Metadata: &Metadata{Version: "1.2.0"}, Created: t1},
+			{Metadata: &Metadata{Version: "1.3.0-beta"}, Created: t2},
+		},
+	}}
+
+	age, err := idx.Age("foo", "1.0.0")
+	if err != nil {
+		t.Fatalf("Age() error = %v", err)
+	}
+	if age.LatestVersion != "1.2.0" {
+		t.Fatalf("Age().LatestVersion = %s, want 1.2.0 (prerelease excluded)", age.LatestVersion)
+	}
+	if age.VersionsBehind != 1 {
+		t.Fatalf("Age().VersionsBehind = %d, want 1 (capped at LatestVersion, not counting the prerelease)", age.VersionsBehind)
+	}
+	if age.Duration != t1.Sub(t0) {
+		t.Fatalf("Age().Duration = %v, want %v", age.Duration, t1.Sub(t0))
+	}
+}
+
+func TestIndexFileAgeUnknownCurrentVersion(t *testing.T) {
+	idx := testIndex("foo", "1.0.0", "1.1.0")
+
+	if _, err := idx.Age("foo", "9.9.9"); err == nil {
+		t.Fatal("expected an error for a current version absent from the index")
+	}
+}
+
+func TestIndexFileReport(t *testing.T) {
+	idx := IndexFile{Entries: map[string]BundleVersions{
+		"foo": {bv("1.0.0"), bv("2.0.0")},
+		"bar": {bv("0.1.0")},
+	}}
+
+	report := idx.Report(map[string]string{
+		"foo":     "1.0.0",
+		"bar":     "0.1.0",
+		"missing": "1.0.0",
+	})
+
+	if len(report) != 2 {
+		t.Fatalf("Report() returned %d entries, want 2 (missing framework skipped)", len(report))
+	}
+}
@@ -15,6 +15,12 @@ type IndexFile struct {
 	APIVersion string                    `json:"apiVersion"`
 	Generated  time.Time                 `json:"generated"`
 	Entries    map[string]BundleVersions `json:"entries"`
+
+	// Verify, when set, makes getFramework fetch and check each candidate
+	// bundle's provenance before resolving to it, refusing any entry whose
+	// Digest doesn't match. It is a repo-level policy knob, not part of the
+	// index file's on-disk format.
+	Verify *VerifyOptions `json:"-"`
 }
 
 // BundleVersions is a list of versioned bundle references.
@@ -24,10 +30,11 @@ type BundleVersions []*BundleVersion
 // BundleVersion represents a framework entry in the IndexFile
 type BundleVersion struct {
 	*Metadata
-	URLs    []string  `json:"urls"`
-	Created time.Time `json:"created,omitempty"`
-	Removed bool      `json:"removed,omitempty"`
-	Digest  string    `json:"digest,omitempty"`
+	URLs      []string  `json:"urls"`
+	Created   time.Time `json:"created,omitempty"`
+	Removed   bool      `json:"removed,omitempty"`
+	Digest    string    `json:"digest,omitempty"`
+	Signature string    `json:"signature,omitempty"`
 }
 
 // Len returns the length.
@@ -110,6 +117,9 @@ func (i IndexFile) getFramework(name string, versionConstraint *semver.Constrain
 		}
 
 		if versionConstraint.Check(test) {
+			if err := i.verifyIfConfigured(ver); err != nil {
+				return nil, errors.Wrapf(err, "resolving %s-%s", name, ver.Version)
+			}
 			return ver, nil
 		}
 	}
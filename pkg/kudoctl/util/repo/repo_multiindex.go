@@ -0,0 +1,170 @@
+package repo
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// IndexSource pairs an IndexFile with the repo URL it was fetched from and
+// a priority used to break ties between sources that both carry the same
+// (name, version). Higher Priority wins; a yanked entry (Removed: true) in
+// a higher-priority source removes that version from the merged result
+// even if a lower-priority source still carries it.
+type IndexSource struct {
+	URL      string
+	Priority int
+	Index    *IndexFile
+}
+
+// MultiIndex composes several IndexFiles, typically one per configured
+// repo, into a single searchable view. It lets users mix an upstream
+// community index with an internal or mirrored one without the two
+// stepping on each other.
+type MultiIndex struct {
+	sources []IndexSource
+}
+
+// NewMultiIndex builds a MultiIndex from sources. Sources are consulted in
+// Priority order (highest first); ties fall back to the order given here.
+func NewMultiIndex(sources ...IndexSource) *MultiIndex {
+	m := &MultiIndex{sources: append([]IndexSource(nil), sources...)}
+	sort.SliceStable(m.sources, func(a, b int) bool {
+		return m.sources[a].Priority > m.sources[b].Priority
+	})
+	return m
+}
+
+// mergedVersion tracks, for a given (name, version), the bundle and
+// source URL of the highest-priority source that carries it, along with
+// that same source's yanked state.
+type mergedVersion struct {
+	bv        *BundleVersion
+	sourceURL string
+	yanked    bool
+}
+
+// merge collects every BundleVersion for name across sources, deduplicated
+// by (name, version). The highest-priority source to carry a given version
+// is the sole authority for it: its bundle (digest included) and its
+// yanked state both win outright, and a lower-priority source's
+// conflicting copy of the same version - whether a different digest or a
+// different yanked state - is dropped rather than kept as a sibling.
+func (m *MultiIndex) merge(name string) map[string]*mergedVersion {
+	out := make(map[string]*mergedVersion)
+
+	// Sources are already ordered highest-priority first, so the first
+	// source we see for a given version key sets both the winning bundle
+	// and its yanked state; later, lower-priority sources can only fill in
+	// versions we haven't seen yet.
+	for _, src := range m.sources {
+		if src.Index == nil {
+			continue
+		}
+		vs, ok := src.Index.Entries[name]
+		if !ok {
+			continue
+		}
+		for _, bv := range vs {
+			key := dedupKey(name, bv)
+			if _, seen := out[key]; seen {
+				continue
+			}
+			out[key] = &mergedVersion{bv: bv, sourceURL: src.URL, yanked: bv.Removed}
+		}
+	}
+	return out
+}
+
+func dedupKey(name string, bv *BundleVersion) string {
+	return fmt.Sprintf("%s@%s", name, bv.Version)
+}
+
+// GetByName returns every non-yanked BundleVersion known for name across
+// all sources, sorted in descending version order.
+func (m *MultiIndex) GetByName(name string) (BundleVersions, error) {
+	merged := m.merge(name)
+
+	var out BundleVersions
+	for _, mv := range merged {
+		if mv.yanked {
+			continue
+		}
+		out = append(out, mv.bv)
+	}
+	if len(out) == 0 {
+		return nil, errors.Errorf("no framework of given name %s found in any source", name)
+	}
+	sort.Sort(sort.Reverse(out))
+	return out, nil
+}
+
+// GetByNameAndVersion returns the BundleVersion for name matching the
+// semver constraint version, preferring the copy from the
+// highest-priority source that carries it, or an error if every source
+// lacks it or has yanked it. If the winning source has a Verify policy
+// configured, it's re-applied here so a MultiIndex can't be used to
+// silently bypass a source's repo-level digest verification.
+func (m *MultiIndex) GetByNameAndVersion(name, version string) (*BundleVersion, error) {
+	merged := m.merge(name)
+
+	var vs BundleVersions
+	for _, mv := range merged {
+		if mv.yanked {
+			continue
+		}
+		vs = append(vs, mv.bv)
+	}
+	if len(vs) == 0 {
+		return nil, errors.Errorf("no framework of given name %s found in any source", name)
+	}
+	sort.Sort(sort.Reverse(vs))
+
+	idx := IndexFile{Entries: map[string]BundleVersions{name: vs}}
+	bv, err := idx.GetByNameAndVersion(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.verifyWinningSource(merged, name, bv); err != nil {
+		return nil, errors.Wrapf(err, "resolving %s-%s", name, bv.Version)
+	}
+	return bv, nil
+}
+
+// verifyWinningSource looks up, within an already-computed merge, which
+// source produced bv and, if that source configured a Verify policy,
+// applies it.
+func (m *MultiIndex) verifyWinningSource(merged map[string]*mergedVersion, name string, bv *BundleVersion) error {
+	mv, ok := merged[dedupKey(name, bv)]
+	if !ok {
+		return nil
+	}
+
+	for _, src := range m.sources {
+		if src.URL != mv.sourceURL || src.Index == nil || src.Index.Verify == nil {
+			continue
+		}
+		verifying := IndexFile{Verify: src.Index.Verify}
+		return verifying.verifyIfConfigured(bv)
+	}
+	return nil
+}
+
+// Sources reports, for every non-yanked version of name visible across all
+// configured sources, the URL of the repo whose merge it won - i.e. which
+// repo actually satisfied the query for that version. Yanked versions are
+// omitted since they're never returned by GetByName/GetByNameAndVersion.
+func (m *MultiIndex) Sources(name string) map[string]string {
+	merged := m.merge(name)
+
+	out := make(map[string]string, len(merged))
+	for _, mv := range merged {
+		if mv.yanked {
+			continue
+		}
+		out[mv.bv.Version] = mv.sourceURL
+	}
+	return out
+}
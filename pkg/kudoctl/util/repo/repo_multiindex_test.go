@@ -0,0 +1,89 @@
+package repo
+
+import "testing"
+
+func TestMultiIndexPriorityWins(t *testing.T) {
+	trusted := &IndexFile{Entries: map[string]BundleVersions{
+		"foo": {&BundleVersion{Metadata: &Metadata{Version: "1.0.0"}, Digest: "sha256:trusted"}},
+	}}
+	mirror := &IndexFile{Entries: map[string]BundleVersions{
+		"foo": {&BundleVersion{Metadata: &Metadata{Version: "1.0.0"}, Digest: "sha256:mirror"}},
+	}}
+
+	m := NewMultiIndex(
+		IndexSource{URL: "mirror", Priority: 1, Index: mirror},
+		IndexSource{URL: "trusted", Priority: 10, Index: trusted},
+	)
+
+	bv, err := m.GetByNameAndVersion("foo", "1.0.0")
+	if err != nil {
+		t.Fatalf("GetByNameAndVersion() error = %v", err)
+	}
+	if bv.Digest != "sha256:trusted" {
+		t.Fatalf("GetByNameAndVersion() digest = %s, want the higher-priority source's digest", bv.Digest)
+	}
+
+	sources := m.Sources("foo")
+	if sources["1.0.0"] != "trusted" {
+		t.Fatalf("Sources()[1.0.0] = %s, want trusted", sources["1.0.0"])
+	}
+}
+
+func TestMultiIndexHigherPriorityYankWins(t *testing.T) {
+	trusted := &IndexFile{Entries: map[string]BundleVersions{
+		"foo": {&BundleVersion{Metadata: &Metadata{Version: "1.0.0"}, Removed: true}},
+	}}
+	mirror := &IndexFile{Entries: map[string]BundleVersions{
+		"foo": {&BundleVersion{Metadata: &Metadata{Version: "1.0.0"}, Removed: false}},
+	}}
+
+	m := NewMultiIndex(
+		IndexSource{URL: "trusted", Priority: 10, Index: trusted},
+		IndexSource{URL: "mirror", Priority: 1, Index: mirror},
+	)
+
+	if _, err := m.GetByName("foo"); err == nil {
+		t.Fatal("expected a higher-priority yank to remove the version globally, got no error")
+	}
+}
+
+func TestMultiIndexLowerPriorityYankDoesNotRemoveVersion(t *testing.T) {
+	trusted := &IndexFile{Entries: map[string]BundleVersions{
+		"foo": {&BundleVersion{Metadata: &Metadata{Version: "1.0.0"}, Removed: false}},
+	}}
+	mirror := &IndexFile{Entries: map[string]BundleVersions{
+		"foo": {&BundleVersion{Metadata: &Metadata{Version: "1.0.0"}, Removed: true}},
+	}}
+
+	m := NewMultiIndex(
+		IndexSource{URL: "trusted", Priority: 10, Index: trusted},
+		IndexSource{URL: "mirror", Priority: 1, Index: mirror},
+	)
+
+	bv, err := m.GetByNameAndVersion("foo", "1.0.0")
+	if err != nil {
+		t.Fatalf("GetByNameAndVersion() error = %v, want the trusted copy to still be served", err)
+	}
+	if bv.Version != "1.0.0" {
+		t.Fatalf("GetByNameAndVersion() = %s, want 1.0.0", bv.Version)
+	}
+}
+
+func TestMultiIndexGetByNameAndVersionVerifiesWinningSource(t *testing.T) {
+	data := []byte("bundle-bytes")
+	trusted := &IndexFile{
+		Entries: map[string]BundleVersions{
+			"foo": {&BundleVersion{Metadata: &Metadata{Version: "1.0.0"}, Digest: Digest(data)}},
+		},
+		Verify: &VerifyOptions{
+			VerifyDigests: true,
+			Fetcher:       fakeFetcher{data: []byte("tampered")},
+		},
+	}
+
+	m := NewMultiIndex(IndexSource{URL: "trusted", Priority: 10, Index: trusted})
+
+	if _, err := m.GetByNameAndVersion("foo", "1.0.0"); err == nil {
+		t.Fatal("expected the winning source's Verify policy to refuse a digest mismatch")
+	}
+}
@@ -0,0 +1,132 @@
+package repo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// DigestAlgorithm is the hash algorithm used to compute a bundle Digest.
+// Only sha256 is currently supported, matching the prefix used in Digest
+// strings (e.g. "sha256:abcd...").
+const DigestAlgorithm = "sha256"
+
+// Keyring abstracts a set of public keys capable of validating a detached
+// signature (PGP or minisign) over bundle or index data. Implementations
+// are expected to wrap whatever keyring format the caller already trusts.
+type Keyring interface {
+	// Verify checks that signature is a valid detached signature over data
+	// produced by a key in the keyring, returning the identity of the
+	// signer on success.
+	Verify(data, signature []byte) (identity string, err error)
+}
+
+// Digest computes the default (sha256) digest of data, formatted as
+// "<algorithm>:<hex>" so it can be compared directly against
+// BundleVersion.Digest.
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("%s:%s", DigestAlgorithm, hex.EncodeToString(sum[:]))
+}
+
+// Verify validates data fetched for this bundle version against the
+// Digest recorded in the index and, if a Signature is present, against
+// keyring. An unsigned bundle is checked against its digest only; a signed
+// bundle with a nil keyring is refused with an error.
+func (b *BundleVersion) Verify(data []byte, keyring Keyring) error {
+	if b.Digest == "" {
+		return errors.New("bundle version has no digest to verify against")
+	}
+
+	if got := Digest(data); got != b.Digest {
+		return errors.Errorf("digest mismatch: expected %s, got %s", b.Digest, got)
+	}
+
+	if b.Signature == "" {
+		return nil
+	}
+	if keyring == nil {
+		return errors.New("bundle version is signed but no keyring was provided")
+	}
+
+	if _, err := keyring.Verify(data, []byte(b.Signature)); err != nil {
+		return errors.Wrap(err, "verifying bundle signature")
+	}
+	return nil
+}
+
+// VerifyIndex validates a detached signature over a raw index file. It is
+// the index-level analogue of BundleVersion.Verify and is typically used
+// to confirm an index.yaml came from a trusted repo maintainer before any
+// of its entries are trusted individually.
+func VerifyIndex(indexData, sigData []byte, keyring Keyring) error {
+	if keyring == nil {
+		return errors.New("no keyring provided to verify index signature")
+	}
+	if _, err := keyring.Verify(indexData, sigData); err != nil {
+		return errors.Wrap(err, "verifying index signature")
+	}
+	return nil
+}
+
+// GetByNameAndVersionVerified behaves like GetByNameAndVersion, but also
+// verifies data (bundle contents the caller has already fetched) against
+// the resolved entry's Digest and Signature. Use this when the caller has
+// fetched the bundle itself and has no BundleFetcher to hand the repo; for
+// repo-level "always verify" policy, set IndexFile.Verify instead so
+// getFramework enforces it for every resolution.
+func (i IndexFile) GetByNameAndVersionVerified(name, version string, data []byte, keyring Keyring) (*BundleVersion, error) {
+	bv, err := i.GetByNameAndVersion(name, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bv.Verify(data, keyring); err != nil {
+		return nil, errors.Wrapf(err, "verifying %s-%s", name, version)
+	}
+	return bv, nil
+}
+
+// BundleFetcher fetches the raw bytes of a bundle, trying each of urls in
+// order until one succeeds. getFramework uses it to materialize a
+// candidate bundle's contents so they can be checked against Digest and
+// Signature before the entry is resolved.
+type BundleFetcher interface {
+	Fetch(urls []string) ([]byte, error)
+}
+
+// VerifyOptions turns on repo-level provenance verification. Setting it on
+// an IndexFile makes getFramework - and therefore GetByName and
+// GetByNameAndVersion - refuse to resolve to any entry whose fetched bytes
+// don't match its Digest.
+type VerifyOptions struct {
+	// VerifyDigests, when true, requires every resolved entry's Digest to
+	// match its fetched bundle contents.
+	VerifyDigests bool
+	// Fetcher retrieves a candidate bundle's contents for verification.
+	// Required when VerifyDigests is true.
+	Fetcher BundleFetcher
+	// Keyring validates a resolved entry's Signature. Entries without a
+	// Signature are checked by digest only; with a nil Keyring, an entry
+	// that carries a Signature is refused.
+	Keyring Keyring
+}
+
+// verifyIfConfigured checks ver's provenance against i.Verify, if set. It
+// is a no-op when i.Verify is nil or VerifyDigests is false.
+func (i IndexFile) verifyIfConfigured(ver *BundleVersion) error {
+	if i.Verify == nil || !i.Verify.VerifyDigests {
+		return nil
+	}
+	if i.Verify.Fetcher == nil {
+		return errors.New("digest verification enabled but no BundleFetcher configured")
+	}
+
+	data, err := i.Verify.Fetcher.Fetch(ver.URLs)
+	if err != nil {
+		return errors.Wrap(err, "fetching bundle for verification")
+	}
+	return ver.Verify(data, i.Verify.Keyring)
+}
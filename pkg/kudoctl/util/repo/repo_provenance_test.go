@@ -0,0 +1,152 @@
+package repo
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakeKeyring struct {
+	identity string
+	err      error
+}
+
+func (f fakeKeyring) Verify(data, signature []byte) (string, error) {
+	return f.identity, f.err
+}
+
+type fakeFetcher struct {
+	data []byte
+	err  error
+}
+
+func (f fakeFetcher) Fetch(urls []string) ([]byte, error) {
+	return f.data, f.err
+}
+
+func TestBundleVersionVerify(t *testing.T) {
+	data := []byte("bundle-bytes")
+	digest := Digest(data)
+
+	tests := []struct {
+		name    string
+		bv      *BundleVersion
+		data    []byte
+		keyring Keyring
+		wantErr bool
+	}{
+		{
+			name:    "digest matches, no signature",
+			bv:      &BundleVersion{Digest: digest},
+			data:    data,
+			wantErr: false,
+		},
+		{
+			name:    "digest mismatch",
+			bv:      &BundleVersion{Digest: digest},
+			data:    []byte("tampered"),
+			wantErr: true,
+		},
+		{
+			name:    "no digest to verify against",
+			bv:      &BundleVersion{},
+			data:    data,
+			wantErr: true,
+		},
+		{
+			name:    "signed but no keyring provided",
+			bv:      &BundleVersion{Digest: digest, Signature: "sig"},
+			data:    data,
+			wantErr: true,
+		},
+		{
+			name:    "signature verified by keyring",
+			bv:      &BundleVersion{Digest: digest, Signature: "sig"},
+			data:    data,
+			keyring: fakeKeyring{identity: "maintainer"},
+			wantErr: false,
+		},
+		{
+			name:    "signature rejected by keyring",
+			bv:      &BundleVersion{Digest: digest, Signature: "sig"},
+			data:    data,
+			keyring: fakeKeyring{err: errors.New("bad signature")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.bv.Verify(tt.data, tt.keyring)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIndexFileVerifyIfConfigured(t *testing.T) {
+	data := []byte("bundle-bytes")
+	digest := Digest(data)
+	bv := &BundleVersion{Metadata: &Metadata{Version: "1.0.0"}, Digest: digest}
+
+	tests := []struct {
+		name    string
+		verify  *VerifyOptions
+		wantErr bool
+	}{
+		{
+			name:    "no verify options configured",
+			verify:  nil,
+			wantErr: false,
+		},
+		{
+			name:    "verify disabled",
+			verify:  &VerifyOptions{VerifyDigests: false},
+			wantErr: false,
+		},
+		{
+			name:    "verify enabled but no fetcher",
+			verify:  &VerifyOptions{VerifyDigests: true},
+			wantErr: true,
+		},
+		{
+			name:    "verify enabled, fetched bytes match digest",
+			verify:  &VerifyOptions{VerifyDigests: true, Fetcher: fakeFetcher{data: data}},
+			wantErr: false,
+		},
+		{
+			name:    "verify enabled, fetched bytes don't match digest",
+			verify:  &VerifyOptions{VerifyDigests: true, Fetcher: fakeFetcher{data: []byte("tampered")}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := IndexFile{Verify: tt.verify}
+			err := idx.verifyIfConfigured(bv)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verifyIfConfigured() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetByNameAndVersionRefusesDigestMismatch(t *testing.T) {
+	data := []byte("bundle-bytes")
+	idx := IndexFile{
+		Entries: map[string]BundleVersions{
+			"flink": {
+				{Metadata: &Metadata{Version: "1.0.0"}, Digest: Digest(data)},
+			},
+		},
+		Verify: &VerifyOptions{
+			VerifyDigests: true,
+			Fetcher:       fakeFetcher{data: []byte("tampered")},
+		},
+	}
+
+	if _, err := idx.GetByNameAndVersion("flink", "1.0.0"); err == nil {
+		t.Fatal("expected digest mismatch to be refused, got nil error")
+	}
+}
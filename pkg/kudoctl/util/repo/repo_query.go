@@ -0,0 +1,220 @@
+package repo
+
+import (
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+)
+
+// ErrWouldDowngrade is returned by Query when the highest version matching
+// spec is lower than the supplied current version. This applies to every
+// spec, exact "vX.Y.Z" pins included; a Query without a current version
+// never returns it.
+var ErrWouldDowngrade = errors.New("query would select a version lower than the current one")
+
+// GetLatest returns the highest non-prerelease version of name. If no
+// non-prerelease versions exist, the highest prerelease is returned
+// instead, mirroring how `go get` falls back when a module has never cut
+// a stable release.
+func (i IndexFile) GetLatest(name string) (*BundleVersion, error) {
+	vs, ok := i.Entries[name]
+	if !ok || len(vs) == 0 {
+		return nil, errors.Errorf("no framework of given name %s found", name)
+	}
+
+	latest := latestMatching(vs, func(*semver.Version) bool { return true }, false)
+	if latest == nil {
+		latest = latestMatching(vs, func(*semver.Version) bool { return true }, true)
+	}
+	if latest == nil {
+		return nil, errors.Errorf("no usable version found for %s", name)
+	}
+
+	if err := i.verifyIfConfigured(latest); err != nil {
+		return nil, errors.Wrapf(err, "resolving %s-%s", name, latest.Version)
+	}
+	return latest, nil
+}
+
+// GetPatch returns the highest version of name sharing currentVersion's
+// major.minor, excluding prereleases unless currentVersion is itself a
+// prerelease. It refuses to return anything lower than currentVersion.
+func (i IndexFile) GetPatch(name, currentVersion string) (*BundleVersion, error) {
+	cur, err := semver.NewVersion(currentVersion)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing current version %s", currentVersion)
+	}
+
+	vs, ok := i.Entries[name]
+	if !ok || len(vs) == 0 {
+		return nil, errors.Errorf("no framework of given name %s found", name)
+	}
+
+	samePatchLine := func(v *semver.Version) bool {
+		return v.Major() == cur.Major() && v.Minor() == cur.Minor()
+	}
+
+	allowPrerelease := cur.Prerelease() != ""
+	bv := latestMatching(vs, samePatchLine, allowPrerelease)
+	if bv == nil {
+		return nil, errors.Errorf("no patch version found for %s at %d.%d.x", name, cur.Major(), cur.Minor())
+	}
+
+	if err := refuseDowngrade(bv, cur); err != nil {
+		return nil, err
+	}
+
+	if err := i.verifyIfConfigured(bv); err != nil {
+		return nil, errors.Wrapf(err, "resolving %s-%s", name, bv.Version)
+	}
+	return bv, nil
+}
+
+// Query resolves spec against the versions known for name and returns the
+// matching BundleVersion. spec may be:
+//
+//   - "latest": the highest non-prerelease version (see GetLatest)
+//   - "patch": the highest version sharing major.minor with currentVersion
+//   - a comparison expression understood by semver.NewConstraint, e.g.
+//     "<1.2.0", "<=1.2.0", ">1.2.0", ">=1.2.0", "~1.2.0", "^1.2.0"
+//   - a bare prefix such as "v1" or "v1.2", matching any version in that
+//     range
+//   - a full "vX.Y.Z" pin, matching that version exactly
+//
+// current, if supplied, is used to resolve "patch" (whose major.minor is
+// taken from it) and to refuse any selection that would be a downgrade
+// (ErrWouldDowngrade). It is variadic so plain "latest"/range queries can
+// omit it; at most one value is used.
+func (i IndexFile) Query(name, spec string, current ...string) (*BundleVersion, error) {
+	var currentVersion string
+	if len(current) > 0 {
+		currentVersion = current[0]
+	}
+
+	switch spec {
+	case "latest":
+		bv, err := i.GetLatest(name)
+		if err != nil {
+			return nil, err
+		}
+		if currentVersion != "" {
+			cur, err := semver.NewVersion(currentVersion)
+			if err == nil {
+				if err := refuseDowngrade(bv, cur); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return bv, nil
+	case "patch":
+		if currentVersion == "" {
+			return nil, errors.New("patch selector requires a current version")
+		}
+		return i.GetPatch(name, currentVersion)
+	}
+
+	constraintSpec := spec
+	switch {
+	case isBareVersionPrefix(spec):
+		constraintSpec = "~" + strings.TrimPrefix(spec, "v")
+	case isBareFullVersion(spec):
+		constraintSpec = "=" + strings.TrimPrefix(spec, "v")
+	}
+
+	constraint, err := semver.NewConstraint(constraintSpec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parsing version spec %s", spec)
+	}
+
+	bv, err := i.getFramework(name, constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	if currentVersion != "" {
+		cur, err := semver.NewVersion(currentVersion)
+		if err == nil {
+			if err := refuseDowngrade(bv, cur); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return bv, nil
+}
+
+// isBareVersionPrefix reports whether spec is a bare "vX" or "vX.Y" prefix
+// match rather than a comparison expression. A full "vX.Y.Z" is not a
+// prefix match - see isBareFullVersion - since treating it as one would
+// silently upgrade what looks like a version pin to the newest patch/minor
+// in that line.
+func isBareVersionPrefix(spec string) bool {
+	if !strings.HasPrefix(spec, "v") {
+		return false
+	}
+	rest := strings.TrimPrefix(spec, "v")
+	if rest == "" || strings.Count(rest, ".") >= 2 {
+		return false
+	}
+	for _, r := range rest {
+		if (r < '0' || r > '9') && r != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// isBareFullVersion reports whether spec is a bare "vX.Y.Z" pin, i.e. a
+// fully-specified version with no comparison operator.
+func isBareFullVersion(spec string) bool {
+	if !strings.HasPrefix(spec, "v") {
+		return false
+	}
+	rest := strings.TrimPrefix(spec, "v")
+	if strings.Count(rest, ".") != 2 {
+		return false
+	}
+	_, err := semver.NewVersion(rest)
+	return err == nil
+}
+
+// latestMatching returns the highest version in vs satisfying match,
+// considering prereleases only when includePrerelease is true. It scans
+// every candidate and tracks the true maximum rather than trusting vs to
+// already be sorted, since callers may build an IndexFile by hand (or via
+// a future decoding path) without going through parseIndexFile's
+// sortPackages.
+func latestMatching(vs BundleVersions, match func(*semver.Version) bool, includePrerelease bool) *BundleVersion {
+	var best *BundleVersion
+	var bestVersion *semver.Version
+	for _, bv := range vs {
+		v, err := semver.NewVersion(bv.Version)
+		if err != nil {
+			continue
+		}
+		if v.Prerelease() != "" && !includePrerelease {
+			continue
+		}
+		if !match(v) {
+			continue
+		}
+		if best == nil || v.GreaterThan(bestVersion) {
+			best = bv
+			bestVersion = v
+		}
+	}
+	return best
+}
+
+// refuseDowngrade returns ErrWouldDowngrade if bv's version is lower than
+// cur.
+func refuseDowngrade(bv *BundleVersion, cur *semver.Version) error {
+	v, err := semver.NewVersion(bv.Version)
+	if err != nil {
+		return nil
+	}
+	if v.LessThan(cur) {
+		return ErrWouldDowngrade
+	}
+	return nil
+}
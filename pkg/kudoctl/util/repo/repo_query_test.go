@@ -0,0 +1,148 @@
+package repo
+
+import (
+	"sort"
+	"testing"
+)
+
+func bv(version string) *BundleVersion {
+	return &BundleVersion{Metadata: &Metadata{Version: version}}
+}
+
+// testIndex builds an IndexFile the way parseIndexFile would hand one to
+// callers: versions present and already sorted descending.
+func testIndex(name string, versions ...string) IndexFile {
+	vs := make(BundleVersions, 0, len(versions))
+	for _, v := range versions {
+		vs = append(vs, bv(v))
+	}
+	sort.Sort(sort.Reverse(vs))
+	return IndexFile{Entries: map[string]BundleVersions{name: vs}}
+}
+
+func TestGetLatest(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "stable versions present, prerelease excluded",
+			versions: []string{"1.0.0", "1.2.0", "1.3.0-beta"},
+			want:     "1.2.0",
+		},
+		{
+			name:     "no stable release falls back to highest prerelease",
+			versions: []string{"1.0.0-alpha", "1.0.0-beta"},
+			want:     "1.0.0-beta",
+		},
+		{
+			name:     "unknown framework",
+			versions: nil,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idx := testIndex("foo", tt.versions...)
+			got, err := idx.GetLatest("foo")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetLatest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.Version != tt.want {
+				t.Fatalf("GetLatest() = %s, want %s", got.Version, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetLatestIgnoresEntryOrder builds Entries by hand, out of descending
+// order, to confirm GetLatest computes the true max instead of trusting
+// vs to already be sorted.
+func TestGetLatestIgnoresEntryOrder(t *testing.T) {
+	idx := IndexFile{Entries: map[string]BundleVersions{
+		"foo": {bv("1.0.0"), bv("2.0.0"), bv("1.5.0")},
+	}}
+
+	got, err := idx.GetLatest("foo")
+	if err != nil {
+		t.Fatalf("GetLatest() error = %v", err)
+	}
+	if got.Version != "2.0.0" {
+		t.Fatalf("GetLatest() = %s, want 2.0.0", got.Version)
+	}
+}
+
+func TestGetPatch(t *testing.T) {
+	idx := testIndex("foo", "1.2.0", "1.2.9", "1.3.0", "2.0.0")
+
+	got, err := idx.GetPatch("foo", "1.2.3")
+	if err != nil {
+		t.Fatalf("GetPatch() error = %v", err)
+	}
+	if got.Version != "1.2.9" {
+		t.Fatalf("GetPatch() = %s, want 1.2.9", got.Version)
+	}
+
+	if _, err := idx.GetPatch("foo", "1.4.0"); err == nil {
+		t.Fatal("expected no patch found for 1.4.x, got nil error")
+	}
+}
+
+func TestGetPatchRefusesDowngrade(t *testing.T) {
+	idx := testIndex("foo", "1.2.0")
+
+	if _, err := idx.GetPatch("foo", "1.2.5"); err != ErrWouldDowngrade {
+		t.Fatalf("GetPatch() error = %v, want ErrWouldDowngrade", err)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	idx := testIndex("foo", "1.2.3", "1.2.9", "1.9.0", "2.0.0")
+
+	tests := []struct {
+		name    string
+		spec    string
+		current string
+		want    string
+		wantErr error
+	}{
+		{name: "latest", spec: "latest", want: "2.0.0"},
+		{name: "patch", spec: "patch", current: "1.2.3", want: "1.2.9"},
+		{name: "range", spec: "<2.0.0", want: "1.9.0"},
+		{name: "caret range", spec: "^1.0.0", want: "1.9.0"},
+		{name: "bare major prefix", spec: "v1", want: "1.9.0"},
+		{name: "bare major.minor prefix", spec: "v1.2", want: "1.2.9"},
+		{name: "bare full version is an exact pin", spec: "v1.2.3", want: "1.2.3"},
+		{name: "latest refuses downgrade", spec: "latest", current: "9.9.9", wantErr: ErrWouldDowngrade},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *BundleVersion
+			var err error
+			if tt.current != "" {
+				got, err = idx.Query("foo", tt.spec, tt.current)
+			} else {
+				got, err = idx.Query("foo", tt.spec)
+			}
+			if tt.wantErr != nil {
+				if err != tt.wantErr {
+					t.Fatalf("Query() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if got.Version != tt.want {
+				t.Fatalf("Query() = %s, want %s", got.Version, tt.want)
+			}
+		})
+	}
+}
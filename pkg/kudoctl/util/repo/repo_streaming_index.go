@@ -0,0 +1,172 @@
+package repo
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/pkg/errors"
+)
+
+// StreamingIndexAPIVersion is the apiVersion value that marks an index as
+// using the v2 streaming format.
+const StreamingIndexAPIVersion = "v2"
+
+// Index is the read-only surface both the v1 IndexFile and the v2
+// StreamingIndex satisfy, so code that only needs lookups doesn't care
+// which on-disk format backed a given repo.
+type Index interface {
+	GetByName(name string) (*BundleVersion, error)
+	GetByNameAndVersion(name, version string) (*BundleVersion, error)
+}
+
+var (
+	_ Index = IndexFile{}
+	_ Index = (*StreamingIndex)(nil)
+)
+
+// streamingHeader is the single JSON header line that begins a v2 index
+// body, ahead of its per-framework entries. NewStreamingIndex checks its
+// APIVersion before trusting anything that follows.
+type streamingHeader struct {
+	APIVersion string `json:"apiVersion"`
+}
+
+// streamingEntry is one line of the v2 JSON-lines body: a single
+// framework name and its versions, pre-sorted descending at generation
+// time so StreamingIndex never has to sort on load.
+type streamingEntry struct {
+	Name     string         `json:"name"`
+	Versions BundleVersions `json:"versions"`
+}
+
+// StreamingIndex is the v2, lazily-decoded counterpart to IndexFile. Where
+// parseIndexFile reads and sorts the whole catalog into memory up front,
+// StreamingIndex keeps only a name -> byte offset table in memory and
+// decodes a single JSON line per Lookup, so it scales to catalogs far
+// larger than is comfortable to hold fully parsed.
+type StreamingIndex struct {
+	body    io.ReadSeeker
+	offsets map[string]int64
+}
+
+// NewStreamingIndex builds a StreamingIndex over body, a v2 JSON-lines
+// stream led by a single JSON header line, using idx - its sidecar ".idx"
+// offset table ("name\toffset" per line, offsets relative to body's start)
+// - to find each framework's line without scanning the whole body. It
+// fails fast if the header's apiVersion isn't StreamingIndexAPIVersion
+// rather than letting a format mismatch surface later as a confusing
+// JSON-decode error out of Lookup.
+func NewStreamingIndex(body io.ReadSeeker, idx io.Reader) (*StreamingIndex, error) {
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.Wrap(err, "seeking to start of index body")
+	}
+
+	header, err := readStreamingHeader(body)
+	if err != nil {
+		return nil, err
+	}
+	if header.APIVersion != StreamingIndexAPIVersion {
+		return nil, errors.Errorf("unsupported streaming index apiVersion %q, expected %q", header.APIVersion, StreamingIndexAPIVersion)
+	}
+
+	offsets := make(map[string]int64)
+
+	scanner := bufio.NewScanner(idx)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		name, offsetStr, ok := strings.Cut(line, "\t")
+		if !ok {
+			return nil, errors.Errorf("malformed .idx line %q", line)
+		}
+		offset, err := strconv.ParseInt(offsetStr, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing offset for %s", name)
+		}
+		offsets[name] = offset
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "reading .idx offset table")
+	}
+
+	return &StreamingIndex{body: body, offsets: offsets}, nil
+}
+
+// readStreamingHeader reads and decodes the leading JSON header line of a
+// v2 index body. body's later absolute-offset seeks in Lookup don't depend
+// on exactly where this leaves the read position, since every read there
+// starts with its own Seek to an absolute offset.
+func readStreamingHeader(body io.Reader) (streamingHeader, error) {
+	line, err := bufio.NewReader(body).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return streamingHeader{}, errors.Wrap(err, "reading index header")
+	}
+
+	var header streamingHeader
+	if err := json.Unmarshal([]byte(strings.TrimSpace(line)), &header); err != nil {
+		return streamingHeader{}, errors.Wrap(err, "parsing index header")
+	}
+	return header, nil
+}
+
+// Lookup decodes and returns the versions known for name, seeking
+// directly to its offset instead of scanning the stream. It returns a nil
+// slice and no error if name isn't present.
+func (s *StreamingIndex) Lookup(name string) (BundleVersions, error) {
+	offset, ok := s.offsets[name]
+	if !ok {
+		return nil, nil
+	}
+
+	if _, err := s.body.Seek(offset, io.SeekStart); err != nil {
+		return nil, errors.Wrapf(err, "seeking to offset for %s", name)
+	}
+
+	var entry streamingEntry
+	if err := json.NewDecoder(s.body).Decode(&entry); err != nil {
+		return nil, errors.Wrapf(err, "decoding entry for %s", name)
+	}
+	if entry.Name != name {
+		return nil, errors.Errorf("offset table corrupt: expected %s, found %s", name, entry.Name)
+	}
+	return entry.Versions, nil
+}
+
+// GetByName returns the framework of given name.
+func (s *StreamingIndex) GetByName(name string) (*BundleVersion, error) {
+	return s.GetByNameAndVersion(name, "*")
+}
+
+// GetByNameAndVersion returns the framework of given name and version.
+func (s *StreamingIndex) GetByNameAndVersion(name, version string) (*BundleVersion, error) {
+	constraint, err := semver.NewConstraint(version)
+	if err != nil {
+		return nil, err
+	}
+
+	vs, err := s.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(vs) == 0 {
+		return nil, fmt.Errorf("no framework of given name %s and version %v found", name, constraint)
+	}
+
+	for _, ver := range vs {
+		test, err := semver.NewVersion(ver.Version)
+		if err != nil {
+			continue
+		}
+		if constraint.Check(test) {
+			return ver, nil
+		}
+	}
+	return nil, fmt.Errorf("no framework version found for %s-%v", name, constraint)
+}
@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildStreamingBody writes a v2 header line followed by one JSON line per
+// entry, and returns the body alongside a matching ".idx" offset table.
+func buildStreamingBody(t *testing.T, entries map[string][]string) (*bytes.Reader, *strings.Reader) {
+	t.Helper()
+
+	var body bytes.Buffer
+	header, err := json.Marshal(streamingHeader{APIVersion: StreamingIndexAPIVersion})
+	if err != nil {
+		t.Fatalf("marshalling header: %v", err)
+	}
+	body.Write(header)
+	body.WriteByte('\n')
+
+	var idx strings.Builder
+	for name, versions := range entries {
+		offset := body.Len()
+		vs := make(BundleVersions, 0, len(versions))
+		for _, v := range versions {
+			vs = append(vs, bv(v))
+		}
+		line, err := json.Marshal(streamingEntry{Name: name, Versions: vs})
+		if err != nil {
+			t.Fatalf("marshalling entry %s: %v", name, err)
+		}
+		body.Write(line)
+		body.WriteByte('\n')
+		fmt.Fprintf(&idx, "%s\t%d\n", name, offset)
+	}
+
+	return bytes.NewReader(body.Bytes()), strings.NewReader(idx.String())
+}
+
+func TestStreamingIndexLookup(t *testing.T) {
+	body, idx := buildStreamingBody(t, map[string][]string{
+		"foo": {"1.0.0", "2.0.0"},
+		"bar": {"0.1.0"},
+	})
+
+	si, err := NewStreamingIndex(body, idx)
+	if err != nil {
+		t.Fatalf("NewStreamingIndex() error = %v", err)
+	}
+
+	got, err := si.GetByNameAndVersion("foo", "^1.0.0")
+	if err != nil {
+		t.Fatalf("GetByNameAndVersion() error = %v", err)
+	}
+	if got.Version != "1.0.0" {
+		t.Fatalf("GetByNameAndVersion() = %s, want 1.0.0", got.Version)
+	}
+
+	got, err = si.GetByName("bar")
+	if err != nil {
+		t.Fatalf("GetByName() error = %v", err)
+	}
+	if got.Version != "0.1.0" {
+		t.Fatalf("GetByName() = %s, want 0.1.0", got.Version)
+	}
+
+	if _, err := si.GetByName("missing"); err == nil {
+		t.Fatal("expected an error for a name absent from the offset table")
+	}
+}
+
+func TestNewStreamingIndexRejectsWrongAPIVersion(t *testing.T) {
+	var body bytes.Buffer
+	header, _ := json.Marshal(streamingHeader{APIVersion: "v1"})
+	body.Write(header)
+	body.WriteByte('\n')
+
+	if _, err := NewStreamingIndex(bytes.NewReader(body.Bytes()), strings.NewReader("")); err == nil {
+		t.Fatal("expected a v1-labeled body to be rejected by NewStreamingIndex")
+	}
+}